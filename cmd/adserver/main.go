@@ -0,0 +1,110 @@
+// Command adserver runs the ad server's HTTP API.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/stevenmays/adserver/internal/httpmw"
+	"github.com/stevenmays/adserver/internal/index"
+	"github.com/stevenmays/adserver/internal/server"
+	"github.com/stevenmays/adserver/internal/storage"
+	"github.com/stevenmays/adserver/process"
+)
+
+// Might be better as env variable - but want this app to be simpler to run
+const defaultBaseURL = "http://localhost:8000"
+
+func main() {
+	var storageBackend string
+	var etcdEndpoints cli.StringSlice
+	var baseURL string
+	var tokensFile string
+
+	httpState := server.NewState()
+	sweeperState := index.NewSweeperState()
+
+	app := &cli.App{
+		Name:  "adserver",
+		Usage: "serves ad decisions and tracks impressions",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "storage-backend",
+				Value:       string(storage.BackendMemory),
+				Usage:       "storage backend to use (memory or etcd)",
+				Destination: &storageBackend,
+			},
+			&cli.StringSliceFlag{
+				Name:        "etcd-endpoint",
+				Usage:       "etcd endpoint to connect to, repeatable; used when -storage-backend=etcd",
+				Destination: &etcdEndpoints,
+			},
+			&cli.StringFlag{
+				Name:        "base-url",
+				Value:       defaultBaseURL,
+				Usage:       "base URL used to build impression URLs returned from ad decisions",
+				Destination: &baseURL,
+			},
+			&cli.StringFlag{
+				Name:        "api-tokens-file",
+				Usage:       "path to a JSON file mapping API tokens to advertiser IDs; falls back to ADSERVER_API_TOKENS if unset",
+				Destination: &tokensFile,
+			},
+		}, append(httpState.Flags(), sweeperState.Flags()...)...),
+		Action: func(cctx *cli.Context) error {
+			store, err := storage.New(storage.Config{
+				Backend:       storage.Backend(storageBackend),
+				EtcdEndpoints: etcdEndpoints.Value(),
+			})
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+
+			tokens, err := httpmw.LoadTokenStore(tokensFile)
+			if err != nil {
+				return fmt.Errorf("loading API tokens: %w", err)
+			}
+
+			states := []process.State{httpState}
+
+			// Index is only safe against the memory backend: it's a
+			// process-local cache with no etcd watch or reconciliation,
+			// so under etcd it would silently diverge from campaigns
+			// another instance creates or caps. Leaving httpState.Index
+			// nil makes adDecisionHandler read through to store instead.
+			backend := storage.Backend(storageBackend)
+			if backend == "" {
+				backend = storage.BackendMemory
+			}
+			if backend == storage.BackendMemory {
+				idx := index.New()
+				existing, err := store.List(context.Background())
+				if err != nil {
+					return fmt.Errorf("loading existing campaigns into index: %w", err)
+				}
+				for _, c := range existing {
+					idx.Add(c)
+				}
+
+				httpState.Index = idx
+				sweeperState.Index = idx
+				states = append(states, sweeperState)
+			}
+
+			httpState.Store = store
+			httpState.BaseURL = baseURL
+			httpState.Tokens = tokens
+
+			fmt.Println("Server is up")
+			return process.Run(cctx.Context, states...)
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
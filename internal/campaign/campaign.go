@@ -0,0 +1,59 @@
+// Package campaign defines the Campaign type shared across the
+// adserver and the validation rules a campaign must satisfy before it
+// can be created.
+package campaign
+
+import "errors"
+
+// ErrInvalid is returned by Validate when a campaign fails one of the
+// required-field checks.
+var ErrInvalid = errors.New("campaign: invalid campaign")
+
+type Campaign struct {
+	ID              int
+	StartTimestamp  int64    `json:"start_timestamp"`
+	EndTimestamp    int64    `json:"end_timestamp"`
+	TargetKeywords  []string `json:"target_keywords"`
+	MaxImpression   int      `json:"max_impression"`
+	CPM             float64  `json:"cpm"`
+	ImpressionCount int
+	ImpressionIds   []string
+
+	// AdvertiserID identifies the advertiser that created this
+	// campaign, as stamped by the httpmw API-token auth middleware
+	// from the bearer token on the creating request. Empty for
+	// campaigns created without token auth configured.
+	AdvertiserID string `json:"-"`
+
+	// NotifyURL, if set, is POSTed an impression callback by the
+	// webhook dispatcher every time one of this campaign's impressions
+	// is recorded. NotifySecret signs that callback; see
+	// internal/webhook.
+	NotifyURL    string `json:"notify_url,omitempty"`
+	NotifySecret string `json:"notify_secret,omitempty"`
+
+	// DeadLetters accumulates impression callbacks the webhook
+	// dispatcher gave up on.
+	DeadLetters []DeadLetter
+}
+
+// DeadLetter records an impression callback the webhook dispatcher
+// never delivered: it exhausted the retry budget, got a non-retryable
+// response, or could not be queued at all (Attempts is then 0).
+type DeadLetter struct {
+	ImpressionID string
+	URL          string
+	Attempts     int
+	LastError    string
+	FailedAt     int64
+}
+
+// Validate reports whether c has every field a new campaign must set.
+// It does not check ID, ImpressionCount, or ImpressionIds, which are
+// assigned by storage rather than supplied by the caller.
+func Validate(c *Campaign) error {
+	if c.StartTimestamp == 0 || c.EndTimestamp == 0 || len(c.TargetKeywords) == 0 || c.MaxImpression == 0 || c.CPM <= 0 {
+		return ErrInvalid
+	}
+	return nil
+}
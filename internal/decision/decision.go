@@ -0,0 +1,83 @@
+// Package decision implements the ad selection algorithm: given the
+// keywords on an incoming ad request, pick the best live campaign to
+// serve.
+package decision
+
+import (
+	"context"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+// SelectCampaign returns the best campaign targeting one of keywords
+// that is live at now and hasn't hit its impression cap, or nil if
+// nothing matches. Ties are broken by earliest EndTimestamp, then by
+// lowest ID, matching the adserver's original selection rules.
+//
+// It checks ctx between campaigns and returns ctx.Err() if the caller's
+// deadline elapses mid-scan, rather than running the full O(N) pass
+// once campaign count makes that expensive.
+func SelectCampaign(ctx context.Context, now int64, keywords []string, campaigns []*campaign.Campaign) (*campaign.Campaign, error) {
+	var selected *campaign.Campaign
+	for _, c := range campaigns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if now < c.StartTimestamp || now >= c.EndTimestamp {
+			continue
+		}
+
+		if c.ImpressionCount >= c.MaxImpression {
+			continue
+		}
+
+		if !hasCommonKeyword(c.TargetKeywords, keywords) {
+			continue
+		}
+
+		// This can be a one liner, but breaking it up for readability
+		if selected == nil {
+			selected = c
+		} else if c.CPM > selected.CPM {
+			selected = c
+		} else if (c.CPM == selected.CPM && c.EndTimestamp < selected.EndTimestamp) || (c.CPM == selected.CPM && c.EndTimestamp == selected.EndTimestamp && c.ID < selected.ID) {
+			selected = c
+		}
+	}
+
+	return selected, nil
+}
+
+// HasCappedMatch reports whether any campaign in campaigns targets one
+// of keywords and is within its flight window at now, but has already
+// hit its impression cap. It exists so callers can distinguish a
+// "capped" outcome from a plain "no_match" for metrics, without
+// duplicating SelectCampaign's matching rules.
+func HasCappedMatch(now int64, keywords []string, campaigns []*campaign.Campaign) bool {
+	for _, c := range campaigns {
+		if now < c.StartTimestamp || now >= c.EndTimestamp {
+			continue
+		}
+		if !hasCommonKeyword(c.TargetKeywords, keywords) {
+			continue
+		}
+		if c.ImpressionCount >= c.MaxImpression {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCommonKeyword evaluates campaign keywords and adRequestKeywords and
+// determines if there's a match.
+func hasCommonKeyword(campaignKeywords, adRequestKeywords []string) bool {
+	for _, campaignKeyword := range campaignKeywords {
+		for _, adRequestKeyword := range adRequestKeywords {
+			if campaignKeyword == adRequestKeyword {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package decision
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+func TestSelectCampaign(t *testing.T) {
+	now := time.Now().Unix()
+
+	live := func(id int, cpm float64, endTimestamp int64, keywords ...string) *campaign.Campaign {
+		return &campaign.Campaign{
+			ID:             id,
+			StartTimestamp: now - 3600,
+			EndTimestamp:   endTimestamp,
+			TargetKeywords: keywords,
+			MaxImpression:  100,
+			CPM:            cpm,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		keywords   []string
+		campaigns  []*campaign.Campaign
+		wantID     int
+		wantNilSel bool
+	}{
+		{
+			name:     "no matching keyword",
+			keywords: []string{"nonexistent"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now+3600, "shampoo"),
+			},
+			wantNilSel: true,
+		},
+		{
+			name:     "highest CPM wins",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 5, now+3600, "shampoo"),
+				live(1002, 10, now+3600, "shampoo"),
+			},
+			wantID: 1002,
+		},
+		{
+			name:     "tie on CPM broken by earliest end timestamp",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now+7200, "shampoo"),
+				live(1002, 10, now+3600, "shampoo"),
+			},
+			wantID: 1002,
+		},
+		{
+			name:     "tie on CPM and end timestamp broken by lowest ID",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1002, 10, now+3600, "shampoo"),
+				live(1001, 10, now+3600, "shampoo"),
+			},
+			wantID: 1001,
+		},
+		{
+			name:     "expired campaign is skipped",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now-1, "shampoo"),
+			},
+			wantNilSel: true,
+		},
+		{
+			name:     "capped campaign is skipped",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				{
+					ID:              1001,
+					StartTimestamp:  now - 3600,
+					EndTimestamp:    now + 3600,
+					TargetKeywords:  []string{"shampoo"},
+					MaxImpression:   1,
+					ImpressionCount: 1,
+					CPM:             10,
+				},
+			},
+			wantNilSel: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := SelectCampaign(context.Background(), now, tc.keywords, tc.campaigns)
+			assert.NoError(t, err)
+			if tc.wantNilSel {
+				assert.Nil(t, selected)
+				return
+			}
+			if assert.NotNil(t, selected) {
+				assert.Equal(t, tc.wantID, selected.ID)
+			}
+		})
+	}
+}
+
+func TestSelectCampaign_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	now := time.Now().Unix()
+	campaigns := []*campaign.Campaign{
+		{
+			ID:             1001,
+			StartTimestamp: now - 3600,
+			EndTimestamp:   now + 3600,
+			TargetKeywords: []string{"shampoo"},
+			MaxImpression:  100,
+			CPM:            10,
+		},
+	}
+
+	selected, err := SelectCampaign(ctx, now, []string{"shampoo"}, campaigns)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, selected)
+}
+
+func TestHasCappedMatch(t *testing.T) {
+	now := time.Now().Unix()
+
+	capped := &campaign.Campaign{
+		ID:              1001,
+		StartTimestamp:  now - 3600,
+		EndTimestamp:    now + 3600,
+		TargetKeywords:  []string{"shampoo"},
+		MaxImpression:   1,
+		ImpressionCount: 1,
+		CPM:             10,
+	}
+
+	assert.True(t, HasCappedMatch(now, []string{"shampoo"}, []*campaign.Campaign{capped}))
+	assert.False(t, HasCappedMatch(now, []string{"nonexistent"}, []*campaign.Campaign{capped}))
+	assert.False(t, HasCappedMatch(now, []string{"shampoo"}, nil))
+}
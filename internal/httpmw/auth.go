@@ -0,0 +1,101 @@
+package httpmw
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TokenStore maps a bearer token to the advertiser ID it authenticates.
+type TokenStore map[string]string
+
+// tokensEnvVar holds tokens as comma-separated token:advertiser_id
+// pairs, used by LoadTokenStore when no config file path is given.
+const tokensEnvVar = "ADSERVER_API_TOKENS"
+
+// LoadTokenStore reads a TokenStore from a JSON config file at path
+// mapping token to advertiser ID. If path is empty, it falls back to
+// the ADSERVER_API_TOKENS environment variable, a comma-separated list
+// of "token:advertiser_id" pairs.
+func LoadTokenStore(path string) (TokenStore, error) {
+	if path == "" {
+		return parseTokensEnv(os.Getenv(tokensEnvVar)), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpmw: reading token config %s: %w", path, err)
+	}
+
+	tokens := make(TokenStore)
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("httpmw: parsing token config %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+func parseTokensEnv(raw string) TokenStore {
+	tokens := make(TokenStore)
+	if raw == "" {
+		return tokens
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		token, advertiserID, ok := strings.Cut(pair, ":")
+		if !ok || token == "" || advertiserID == "" {
+			continue
+		}
+		tokens[token] = advertiserID
+	}
+	return tokens
+}
+
+type contextKey string
+
+const advertiserIDKey contextKey = "advertiser_id"
+
+// AdvertiserIDFromContext returns the advertiser ID TokenAuth stamped
+// onto the request context, if any.
+func AdvertiserIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(advertiserIDKey).(string)
+	return id, ok
+}
+
+// TokenAuth requires a valid "Authorization: Bearer <token>" header,
+// comparing the supplied token against tokens with
+// subtle.ConstantTimeCompare to avoid leaking validity through timing,
+// and stamps the matching advertiser ID onto the request context for
+// downstream handlers to read via AdvertiserIDFromContext. A request
+// with no matching token gets a 401 and never reaches next.
+func TokenAuth(tokens TokenStore) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			advertiserID, ok := authenticate(tokens, r.Header.Get("Authorization"))
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), advertiserIDKey, advertiserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func authenticate(tokens TokenStore, authHeader string) (advertiserID string, ok bool) {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(authHeader, prefix) {
+		return "", false
+	}
+	token := strings.TrimPrefix(authHeader, prefix)
+
+	for candidate, id := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package httpmw
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader propagates a request's correlation ID, accepted from
+// the caller or generated by RequestLogging when absent.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestLogging logs each request's method, path, status, duration,
+// and request ID once it completes, and echoes the request ID back on
+// the response. If logger is nil, log.Default() is used.
+func RequestLogging(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				if id, err := uuid.NewRandom(); err == nil {
+					requestID = id.String()
+				}
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+
+			logger.Printf("request_id=%s method=%s path=%s status=%d duration=%s",
+				requestID, r.Method, r.URL.Path, rec.status, time.Since(start))
+		})
+	}
+}
+
+// statusRecorder captures the status code a handler wrote so
+// RequestLogging can include it after ServeHTTP returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,50 @@
+package httpmw
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Decision outcome labels recorded by ObserveDecisionOutcome.
+const (
+	OutcomeServed  = "served"
+	OutcomeNoMatch = "no_match"
+	OutcomeCapped  = "capped"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "adserver_http_request_duration_seconds",
+		Help:    "Latency of adserver HTTP handlers, by handler name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler"})
+
+	decisionOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adserver_decision_outcomes_total",
+		Help: "Count of ad decisions by outcome (served, no_match, capped).",
+	}, []string{"outcome"})
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration, decisionOutcomes)
+}
+
+// ObserveDecisionOutcome increments the decision outcome counter for
+// outcome, one of OutcomeServed, OutcomeNoMatch, or OutcomeCapped.
+func ObserveDecisionOutcome(outcome string) {
+	decisionOutcomes.WithLabelValues(outcome).Inc()
+}
+
+// Metrics times every request that reaches next and records it under
+// requestDuration with the given handler label.
+func Metrics(handler string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			next.ServeHTTP(w, r)
+			requestDuration.WithLabelValues(handler).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Package httpmw provides composable HTTP middleware for the
+// adserver's handlers: API-token authentication, structured request
+// logging, and Prometheus metrics.
+package httpmw
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behavior.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes mw into a single Middleware that applies them in the
+// order given: the first middleware in mw is outermost, so it sees the
+// request before (and the response after) the ones that follow it.
+func Chain(mw ...Middleware) Middleware {
+	return func(final http.Handler) http.Handler {
+		h := final
+		for i := len(mw) - 1; i >= 0; i-- {
+			h = mw[i](h)
+		}
+		return h
+	}
+}
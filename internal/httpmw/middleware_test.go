@@ -0,0 +1,89 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain_RunsMiddlewareInOrder(t *testing.T) {
+	var order []string
+
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	h := Chain(record("outer"), record("inner"))(final)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestTokenAuth_RejectsMissingOrWrongToken(t *testing.T) {
+	tokens := TokenStore{"good-token": "adv-1"}
+	reached := false
+	h := TokenAuth(tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/campaign", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.False(t, reached)
+}
+
+func TestTokenAuth_AcceptsValidTokenAndStampsAdvertiserID(t *testing.T) {
+	tokens := TokenStore{"good-token": "adv-1"}
+	var gotAdvertiserID string
+	h := TokenAuth(tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAdvertiserID, _ = AdvertiserIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/campaign", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "adv-1", gotAdvertiserID)
+}
+
+func TestRequestLogging_GeneratesAndEchoesRequestID(t *testing.T) {
+	h := RequestLogging(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.NotEmpty(t, rec.Header().Get(RequestIDHeader))
+}
+
+func TestRequestLogging_PreservesCallerSuppliedRequestID(t *testing.T) {
+	h := RequestLogging(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "caller-id")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, "caller-id", rec.Header().Get(RequestIDHeader))
+}
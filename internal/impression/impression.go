@@ -0,0 +1,63 @@
+// Package impression records ad impressions against campaign state:
+// allocating the impression ID returned from an ad decision, and later
+// crediting a campaign's impression count when that impression is
+// reported back.
+package impression
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/storage"
+)
+
+// ErrNotFound is returned by Record when impressionID wasn't issued for
+// any known campaign.
+var ErrNotFound = errors.New("impression: unknown impression id")
+
+// Track appends impressionID to the campaign's ImpressionIds so a later
+// Record call can find it.
+func Track(ctx context.Context, store storage.Interface, campaignID int, impressionID string) error {
+	_, err := store.GuaranteedUpdate(ctx, campaignID, func(current *campaign.Campaign) (*campaign.Campaign, error) {
+		current.ImpressionIds = append(current.ImpressionIds, impressionID)
+		return current, nil
+	})
+	return err
+}
+
+// Record credits the campaign that impressionID was issued for with one
+// more served impression, and returns that campaign's updated state so
+// the caller can act on fields like NotifyURL.
+func Record(ctx context.Context, store storage.Interface, impressionID string) (*campaign.Campaign, error) {
+	campaigns, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched *campaign.Campaign
+	for _, c := range campaigns {
+		if containsImpression(c.ImpressionIds, impressionID) {
+			matched = c
+			break
+		}
+	}
+
+	if matched == nil {
+		return nil, ErrNotFound
+	}
+
+	return store.GuaranteedUpdate(ctx, matched.ID, func(current *campaign.Campaign) (*campaign.Campaign, error) {
+		current.ImpressionCount++
+		return current, nil
+	})
+}
+
+func containsImpression(impressionIDs []string, impressionID string) bool {
+	for _, a := range impressionIDs {
+		if a == impressionID {
+			return true
+		}
+	}
+	return false
+}
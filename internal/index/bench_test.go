@@ -0,0 +1,62 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/decision"
+)
+
+// buildCampaigns fans n campaigns out evenly across keywordCount
+// keywords, so a single-keyword ad request only matches a fraction of
+// the total campaign set, the way a real keyword catalog would.
+func buildCampaigns(n, keywordCount int) []*campaign.Campaign {
+	now := time.Now().Unix()
+	campaigns := make([]*campaign.Campaign, n)
+	for i := 0; i < n; i++ {
+		campaigns[i] = &campaign.Campaign{
+			ID:             1001 + i,
+			StartTimestamp: now - 3600,
+			EndTimestamp:   now + 3600,
+			TargetKeywords: []string{fmt.Sprintf("keyword-%d", i%keywordCount)},
+			MaxImpression:  1 << 30,
+			CPM:            float64(i%100) + 1,
+		}
+	}
+	return campaigns
+}
+
+func benchmarkLinear(b *testing.B, n int) {
+	campaigns := buildCampaigns(n, 1000)
+	now := time.Now().Unix()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = decision.SelectCampaign(ctx, now, []string{"keyword-0"}, campaigns)
+	}
+}
+
+func benchmarkIndexed(b *testing.B, n int) {
+	campaigns := buildCampaigns(n, 1000)
+	idx := New()
+	for _, c := range campaigns {
+		idx.Add(c)
+	}
+	now := time.Now().Unix()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = idx.SelectCampaign(ctx, now, []string{"keyword-0"})
+	}
+}
+
+func BenchmarkLinearSelectCampaign_10k(b *testing.B)  { benchmarkLinear(b, 10_000) }
+func BenchmarkLinearSelectCampaign_100k(b *testing.B) { benchmarkLinear(b, 100_000) }
+
+func BenchmarkIndexSelectCampaign_10k(b *testing.B)  { benchmarkIndexed(b, 10_000) }
+func BenchmarkIndexSelectCampaign_100k(b *testing.B) { benchmarkIndexed(b, 100_000) }
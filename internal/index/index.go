@@ -0,0 +1,291 @@
+// Package index maintains a keyword-to-campaign inverted index with
+// per-keyword max-heaps ordered by CPM, so the best live campaign for
+// a set of keywords can be found without scanning every campaign the
+// way decision.SelectCampaign does.
+//
+// Index caches each campaign's liveness fields (StartTimestamp,
+// EndTimestamp, MaxImpression, ImpressionCount) in process memory, and
+// is only ever updated by this process's own Add/IncrementImpression
+// calls: there's no etcd watch or periodic reconciliation against
+// storage. That makes it correct only when exactly one adserver
+// instance can ever write the campaigns it serves, i.e. the memory
+// storage backend. Callers running the etcd backend, where more than
+// one instance can share campaign state, must not wire an Index into
+// decisions and should read through to storage on every request
+// instead (see internal/server.New's idx parameter).
+package index
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+// entry is what a per-keyword heap orders: a campaign ID plus the
+// fields its tie-break rules need.
+type entry struct {
+	id    int
+	cpm   float64
+	endTs int64
+}
+
+// better reports whether a ranks above b under the same tie-break
+// rules as decision.SelectCampaign: highest CPM wins, ties broken by
+// earliest EndTimestamp, further ties broken by lowest ID.
+func better(a, b entry) bool {
+	if a.cpm != b.cpm {
+		return a.cpm > b.cpm
+	}
+	if a.endTs != b.endTs {
+		return a.endTs < b.endTs
+	}
+	return a.id < b.id
+}
+
+// entryHeap is a container/heap.Interface ordering entries so the best
+// one (per better) is always at index 0.
+type entryHeap []entry
+
+func (h entryHeap) Len() int            { return len(h) }
+func (h entryHeap) Less(i, j int) bool  { return better(h[i], h[j]) }
+func (h entryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x interface{}) { *h = append(*h, x.(entry)) }
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// campaignSet is the per-keyword max-heap of live campaign entries,
+// plus every entry ever added under the keyword (all), which best never
+// evicts from.
+type campaignSet struct {
+	mu   sync.Mutex
+	heap entryHeap
+	all  []entry
+}
+
+func (s *campaignSet) add(e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	heap.Push(&s.heap, e)
+	s.all = append(s.all, e)
+}
+
+// best returns the entry at the top of the heap, popping any entries
+// isLive reports as dead along the way, and reports false once the
+// heap is exhausted without finding a live one.
+func (s *campaignSet) best(now int64, isLive func(id int, now int64) bool) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.heap.Len() > 0 {
+		top := s.heap[0]
+		if isLive(top.id, now) {
+			return top, true
+		}
+		heap.Pop(&s.heap)
+	}
+	return entry{}, false
+}
+
+// hasCapped reports whether any entry ever added to the set satisfies
+// isCapped. Unlike best, it doesn't stop at the first dead top and
+// doesn't evict: it scans all, since best pops capped entries off the
+// heap and a capped entry can also sit behind a higher-CPM live one.
+func (s *campaignSet) hasCapped(now int64, isCapped func(id int, now int64) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.all {
+		if isCapped(e.id, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Index is a keyword-to-campaign inverted index. The zero value isn't
+// usable; construct one with New.
+type Index struct {
+	mu        sync.Mutex
+	campaigns map[int]*campaign.Campaign
+	byKeyword map[string]*campaignSet
+}
+
+// New returns an empty Index.
+func New() *Index {
+	return &Index{
+		campaigns: make(map[int]*campaign.Campaign),
+		byKeyword: make(map[string]*campaignSet),
+	}
+}
+
+// Add indexes c under each of its target keywords. Call it once, after
+// a campaign is created and assigned an ID. Add is a no-op on a nil
+// Index, so callers that only wire one in for backends where it's
+// actually safe (see the package doc) don't need to guard every call.
+func (idx *Index) Add(c *campaign.Campaign) {
+	if idx == nil {
+		return
+	}
+
+	idx.mu.Lock()
+	idx.campaigns[c.ID] = c
+	idx.mu.Unlock()
+
+	e := entry{id: c.ID, cpm: c.CPM, endTs: c.EndTimestamp}
+	for _, kw := range c.TargetKeywords {
+		idx.setFor(kw).add(e)
+	}
+}
+
+func (idx *Index) setFor(keyword string) *campaignSet {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	s, ok := idx.byKeyword[keyword]
+	if !ok {
+		s = &campaignSet{}
+		idx.byKeyword[keyword] = s
+	}
+	return s
+}
+
+// IncrementImpression records one more served impression against
+// campaign id, so a later SelectCampaign sees it if that pushes the
+// campaign over its cap. Call it everywhere impression.Record
+// increments ImpressionCount in storage. IncrementImpression is a
+// no-op on a nil Index, for the same reason Add is.
+func (idx *Index) IncrementImpression(id int) {
+	if idx == nil {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if c, ok := idx.campaigns[id]; ok {
+		c.ImpressionCount++
+	}
+}
+
+func (idx *Index) isLive(id int, now int64) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	c, ok := idx.campaigns[id]
+	if !ok {
+		return false
+	}
+	if now < c.StartTimestamp || now >= c.EndTimestamp {
+		return false
+	}
+	return c.ImpressionCount < c.MaxImpression
+}
+
+func (idx *Index) isCapped(id int, now int64) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	c, ok := idx.campaigns[id]
+	if !ok {
+		return false
+	}
+	if now < c.StartTimestamp || now >= c.EndTimestamp {
+		return false
+	}
+	return c.ImpressionCount >= c.MaxImpression
+}
+
+// HasCappedMatch reports whether any campaign indexed under one of
+// keywords is within its flight window at now but has already hit its
+// impression cap. SelectCampaign can't distinguish this from "no
+// match" on its own, since a capped campaign is simply skipped as not
+// live; callers use HasCappedMatch to tell the two outcomes apart for
+// metrics.
+func (idx *Index) HasCappedMatch(now int64, keywords []string) bool {
+	for _, kw := range keywords {
+		idx.mu.Lock()
+		s, ok := idx.byKeyword[kw]
+		idx.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if s.hasCapped(now, idx.isCapped) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectCampaign returns the best live campaign targeting one of
+// keywords at now, or nil if nothing matches. It merges the top of
+// each keyword's heap via a k-way comparison, evicting dead entries it
+// encounters along the way (peek, pop-if-dead, repeat), rather than
+// scanning every campaign the way decision.SelectCampaign does.
+func (idx *Index) SelectCampaign(ctx context.Context, now int64, keywords []string) (*campaign.Campaign, error) {
+	var best *entry
+
+	for _, kw := range keywords {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		idx.mu.Lock()
+		s, ok := idx.byKeyword[kw]
+		idx.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		e, ok := s.best(now, idx.isLive)
+		if !ok {
+			continue
+		}
+
+		if best == nil || better(e, *best) {
+			candidate := e
+			best = &candidate
+		}
+	}
+
+	if best == nil {
+		return nil, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.campaigns[best.id], nil
+}
+
+// StartSweeper runs a background goroutine that evicts each keyword's
+// dead heap entries every interval, until ctx is cancelled, so
+// campaigns that expire without ever being selected again don't pile
+// up in the index indefinitely.
+func (idx *Index) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				idx.sweep(time.Now().Unix())
+			}
+		}
+	}()
+}
+
+func (idx *Index) sweep(now int64) {
+	idx.mu.Lock()
+	sets := make([]*campaignSet, 0, len(idx.byKeyword))
+	for _, s := range idx.byKeyword {
+		sets = append(sets, s)
+	}
+	idx.mu.Unlock()
+
+	for _, s := range sets {
+		s.best(now, idx.isLive)
+	}
+}
@@ -0,0 +1,183 @@
+package index
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+func TestIndex_SelectCampaign(t *testing.T) {
+	now := time.Now().Unix()
+
+	live := func(id int, cpm float64, endTimestamp int64, keywords ...string) *campaign.Campaign {
+		return &campaign.Campaign{
+			ID:             id,
+			StartTimestamp: now - 3600,
+			EndTimestamp:   endTimestamp,
+			TargetKeywords: keywords,
+			MaxImpression:  100,
+			CPM:            cpm,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		keywords   []string
+		campaigns  []*campaign.Campaign
+		wantID     int
+		wantNilSel bool
+	}{
+		{
+			name:     "no matching keyword",
+			keywords: []string{"nonexistent"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now+3600, "shampoo"),
+			},
+			wantNilSel: true,
+		},
+		{
+			name:     "highest CPM wins",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 5, now+3600, "shampoo"),
+				live(1002, 10, now+3600, "shampoo"),
+			},
+			wantID: 1002,
+		},
+		{
+			name:     "tie on CPM broken by earliest end timestamp",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now+7200, "shampoo"),
+				live(1002, 10, now+3600, "shampoo"),
+			},
+			wantID: 1002,
+		},
+		{
+			name:     "tie on CPM and end timestamp broken by lowest ID",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1002, 10, now+3600, "shampoo"),
+				live(1001, 10, now+3600, "shampoo"),
+			},
+			wantID: 1001,
+		},
+		{
+			name:     "expired campaign is skipped",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 10, now-1, "shampoo"),
+			},
+			wantNilSel: true,
+		},
+		{
+			name:     "capped campaign is skipped",
+			keywords: []string{"shampoo"},
+			campaigns: []*campaign.Campaign{
+				{
+					ID:              1001,
+					StartTimestamp:  now - 3600,
+					EndTimestamp:    now + 3600,
+					TargetKeywords:  []string{"shampoo"},
+					MaxImpression:   1,
+					ImpressionCount: 1,
+					CPM:             10,
+				},
+			},
+			wantNilSel: true,
+		},
+		{
+			name:     "best result merges across multiple keywords",
+			keywords: []string{"shampoo", "soap"},
+			campaigns: []*campaign.Campaign{
+				live(1001, 5, now+3600, "shampoo"),
+				live(1002, 10, now+3600, "soap"),
+			},
+			wantID: 1002,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			idx := New()
+			for _, c := range tc.campaigns {
+				idx.Add(c)
+			}
+
+			selected, err := idx.SelectCampaign(context.Background(), now, tc.keywords)
+			assert.NoError(t, err)
+			if tc.wantNilSel {
+				assert.Nil(t, selected)
+				return
+			}
+			if assert.NotNil(t, selected) {
+				assert.Equal(t, tc.wantID, selected.ID)
+			}
+		})
+	}
+}
+
+func TestIndex_SelectCampaign_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	now := time.Now().Unix()
+	idx := New()
+	idx.Add(&campaign.Campaign{
+		ID:             1001,
+		StartTimestamp: now - 3600,
+		EndTimestamp:   now + 3600,
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	})
+
+	selected, err := idx.SelectCampaign(ctx, now, []string{"shampoo"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, selected)
+}
+
+func TestIndex_IncrementImpressionEvictsOnceCapped(t *testing.T) {
+	now := time.Now().Unix()
+	idx := New()
+	idx.Add(&campaign.Campaign{
+		ID:             1001,
+		StartTimestamp: now - 3600,
+		EndTimestamp:   now + 3600,
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  1,
+		CPM:            10,
+	})
+
+	selected, err := idx.SelectCampaign(context.Background(), now, []string{"shampoo"})
+	assert.NoError(t, err)
+	assert.NotNil(t, selected)
+
+	idx.IncrementImpression(1001)
+
+	selected, err = idx.SelectCampaign(context.Background(), now, []string{"shampoo"})
+	assert.NoError(t, err)
+	assert.Nil(t, selected)
+}
+
+func TestIndex_SweepEvictsExpiredEntries(t *testing.T) {
+	now := time.Now().Unix()
+	idx := New()
+	idx.Add(&campaign.Campaign{
+		ID:             1001,
+		StartTimestamp: now - 3600,
+		EndTimestamp:   now - 1,
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	})
+
+	idx.sweep(now)
+
+	s := idx.byKeyword["shampoo"]
+	assert.Equal(t, 0, s.heap.Len())
+}
@@ -0,0 +1,48 @@
+package index
+
+import (
+	"context"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultSweepInterval is how often SweeperState sweeps expired
+// entries out of Index when -index-sweep-interval isn't set.
+const DefaultSweepInterval = time.Minute
+
+// SweeperState runs an Index's sweeper as a process.State, so it
+// starts and stops alongside the HTTP server under the same signal
+// handling instead of being an unmanaged background goroutine. Index
+// must be set before Run is called.
+type SweeperState struct {
+	Index    *Index
+	Interval time.Duration
+}
+
+// NewSweeperState returns a SweeperState that sweeps every
+// DefaultSweepInterval, overridable via the -index-sweep-interval flag
+// registered in Flags.
+func NewSweeperState() *SweeperState {
+	return &SweeperState{Interval: DefaultSweepInterval}
+}
+
+func (s *SweeperState) Name() string { return "index-sweeper" }
+
+func (s *SweeperState) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:        "index-sweep-interval",
+			Value:       s.Interval,
+			Usage:       "how often the campaign index sweeps expired entries",
+			Destination: &s.Interval,
+		},
+	}
+}
+
+// Run starts Index's sweeper and blocks until ctx is cancelled.
+func (s *SweeperState) Run(ctx context.Context) error {
+	s.Index.StartSweeper(ctx, s.Interval)
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requestDeadlineHeader lets a caller bound how long it's willing to
+// wait for a single request, in milliseconds. Handlers fall back to
+// Server.defaultRequestDeadline when it's absent or malformed.
+const requestDeadlineHeader = "X-Request-Deadline-Ms"
+
+// defaultRequestDeadline is used when a request doesn't set
+// X-Request-Deadline-Ms.
+const defaultRequestDeadline = 5 * time.Second
+
+// requestContext derives a context bounded by the request's deadline:
+// the caller-supplied X-Request-Deadline-Ms header if present and
+// valid, otherwise s.defaultRequestDeadline (or defaultRequestDeadline
+// if that's unset). The returned cancel func must be called once the
+// handler is done, same as context.WithTimeout.
+func (s *Server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	d := s.defaultRequestDeadline
+	if d <= 0 {
+		d = defaultRequestDeadline
+	}
+
+	if raw := r.Header.Get(requestDeadlineHeader); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			d = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	return context.WithTimeout(r.Context(), d)
+}
@@ -0,0 +1,303 @@
+// Package server wires the adserver's HTTP routes to the campaign,
+// decision, and impression packages.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/decision"
+	"github.com/stevenmays/adserver/internal/httpmw"
+	"github.com/stevenmays/adserver/internal/impression"
+	"github.com/stevenmays/adserver/internal/index"
+	"github.com/stevenmays/adserver/internal/storage"
+	"github.com/stevenmays/adserver/internal/webhook"
+)
+
+// AdRequest is the body accepted by the ad decision endpoint.
+type AdRequest struct {
+	Keywords []string
+}
+
+// Server holds the dependencies the adserver's handlers need.
+type Server struct {
+	store    storage.Interface
+	baseURL  string
+	webhooks *webhook.Dispatcher
+	tokens   httpmw.TokenStore
+	index    *index.Index
+
+	// defaultRequestDeadline is used when a request doesn't send
+	// X-Request-Deadline-Ms. Zero means defaultRequestDeadline.
+	defaultRequestDeadline time.Duration
+}
+
+// New returns a Server that serves campaigns out of store. baseURL is
+// used to build the impression URL returned from an ad decision.
+// tokens authenticates POST /campaign; a nil or empty TokenStore means
+// every create request is rejected, since no request would carry a
+// recognizable token. idx is the keyword index adDecisionHandler
+// prefers for ad selection, kept in sync by campaignHandler and
+// impressionHandler as campaigns are created and served; idx must be
+// nil unless store can only ever be written by this one instance (see
+// internal/index's package doc), since adDecisionHandler falls back to
+// a per-request store.List + decision.SelectCampaign scan whenever idx
+// is nil, which is the only way to stay correct against a shared etcd
+// backend.
+func New(store storage.Interface, baseURL string, tokens httpmw.TokenStore, idx *index.Index) *Server {
+	return &Server{
+		store:    store,
+		baseURL:  baseURL,
+		webhooks: webhook.NewDispatcher(store, webhook.DefaultWorkers, webhook.DefaultMaxAttempts),
+		tokens:   tokens,
+		index:    idx,
+	}
+}
+
+// Handler returns the adserver's routes: POST /campaign, GET
+// /campaign/{id}/deliveries, POST /addecision, and GET
+// /{impression_id}, each wrapped in request logging and metrics
+// middleware; /campaign and /campaign/ additionally require a valid
+// API token, since both expose or act on a single advertiser's
+// campaign data.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/campaign", httpmw.Chain(
+		httpmw.TokenAuth(s.tokens),
+		httpmw.RequestLogging(nil),
+		httpmw.Metrics("campaign"),
+	)(http.HandlerFunc(s.campaignHandler)))
+	mux.Handle("/campaign/", httpmw.Chain(
+		httpmw.TokenAuth(s.tokens),
+		httpmw.RequestLogging(nil),
+		httpmw.Metrics("deliveries"),
+	)(http.HandlerFunc(s.deliveriesHandler)))
+	mux.Handle("/addecision", httpmw.Chain(
+		httpmw.RequestLogging(nil),
+		httpmw.Metrics("addecision"),
+	)(http.HandlerFunc(s.adDecisionHandler)))
+	mux.Handle("/", httpmw.Chain(
+		httpmw.RequestLogging(nil),
+		httpmw.Metrics("impression"),
+	)(http.HandlerFunc(s.impressionHandler)))
+	return mux
+}
+
+/**
+ * Creates a new campaign.
+ */
+func (s *Server) campaignHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var c campaign.Campaign
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil || campaign.Validate(&c) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if advertiserID, ok := httpmw.AdvertiserIDFromContext(r.Context()); ok {
+		c.AdvertiserID = advertiserID
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	if err := s.store.Create(ctx, &c); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.index.Add(&c)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		CampaignID int `json:"campaign_id"`
+	}{CampaignID: c.ID})
+}
+
+/**
+* Creates an ad decision
+ */
+func (s *Server) adDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var adRequest AdRequest
+	err := json.NewDecoder(r.Body).Decode(&adRequest)
+	if err != nil || len(adRequest.Keywords) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	now := time.Now().Unix()
+	selected, hasCappedMatch, err := s.selectCampaign(ctx, now, adRequest.Keywords)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if selected == nil {
+		outcome := httpmw.OutcomeNoMatch
+		if hasCappedMatch {
+			outcome = httpmw.OutcomeCapped
+		}
+		httpmw.ObserveDecisionOutcome(outcome)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	httpmw.ObserveDecisionOutcome(httpmw.OutcomeServed)
+
+	impressionID := generateUUID()
+	if err := impression.Track(ctx, s.store, selected.ID, impressionID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	impressionURL := s.baseURL + "/" + impressionID
+	json.NewEncoder(w).Encode(struct {
+		CampaignID    int    `json:"campaign_id"`
+		ImpressionURL string `json:"impression_url"`
+	}{CampaignID: selected.ID, ImpressionURL: impressionURL})
+}
+
+// selectCampaign picks the best live campaign for keywords at now. It
+// prefers s.index when one is wired in (see New's doc on when that's
+// safe); otherwise it reads every campaign from s.store on each call
+// and scans it with decision.SelectCampaign, which is always correct
+// no matter how many other adserver instances are writing to store.
+func (s *Server) selectCampaign(ctx context.Context, now int64, keywords []string) (selected *campaign.Campaign, hasCappedMatch bool, err error) {
+	if s.index != nil {
+		selected, err = s.index.SelectCampaign(ctx, now, keywords)
+		if err != nil {
+			return nil, false, err
+		}
+		if selected == nil {
+			hasCappedMatch = s.index.HasCappedMatch(now, keywords)
+		}
+		return selected, hasCappedMatch, nil
+	}
+
+	campaigns, err := s.store.List(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	selected, err = decision.SelectCampaign(ctx, now, keywords, campaigns)
+	if err != nil {
+		return nil, false, err
+	}
+	if selected == nil {
+		hasCappedMatch = decision.HasCappedMatch(now, keywords, campaigns)
+	}
+	return selected, hasCappedMatch, nil
+}
+
+/**
+* Handles an impression callback
+ */
+func (s *Server) impressionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	impressionID := strings.TrimPrefix(r.URL.Path, "/")
+	if len(impressionID) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	c, err := impression.Record(ctx, s.store, impressionID)
+	if err != nil {
+		if err == impression.ErrNotFound {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.index.IncrementImpression(c.ID)
+
+	s.webhooks.Enqueue(ctx, c, impressionID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+* Returns the delivery state of every impression callback the webhook
+* dispatcher has attempted for a campaign. Restricted to the
+* campaign's own advertiser: TokenAuth only proves the caller holds
+* some valid token, not that it's this campaign's token.
+ */
+func (s *Server) deliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "campaign" || parts[2] != "deliveries" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	campaignID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	c, err := s.store.Get(ctx, campaignID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	advertiserID, _ := httpmw.AdvertiserIDFromContext(r.Context())
+	if advertiserID == "" || c.AdvertiserID != advertiserID {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	json.NewEncoder(w).Encode(s.webhooks.Deliveries(campaignID))
+}
+
+/**
+* Generate a uuid and throw if the package fails to generate
+ */
+func generateUUID() string {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		log.Fatalf("Failed to generate UUID: %v", err)
+	}
+	return id.String()
+}
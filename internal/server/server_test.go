@@ -0,0 +1,361 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/httpmw"
+	"github.com/stevenmays/adserver/internal/index"
+	"github.com/stevenmays/adserver/internal/storage"
+)
+
+// TestAdDecisionHandler_DeadlineExceeded exercises the request-deadline
+// path by handing the handler an already-cancelled request context
+// directly, rather than via a real slow backend: requestContext derives
+// from r.Context(), so a request built on a cancelled context yields an
+// already-done ctx without needing to wait for anything.
+func TestAdDecisionHandler_DeadlineExceeded(t *testing.T) {
+	s := New(storage.NewMemory(), "http://localhost:8000", nil, index.New())
+
+	jsonData, err := json.Marshal(AdRequest{Keywords: []string{"shampoo"}})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest(http.MethodPost, "/addecision", bytes.NewReader(jsonData)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	s.adDecisionHandler(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// TestAdDecisionHandler_FallsBackToStoreScanWhenIndexIsNil exercises the
+// path a Server wired with a nil index (as main.go does for the etcd
+// backend) takes: a campaign that reached store by some means other
+// than this Server's own campaignHandler - exactly what a sibling
+// instance sharing etcd would produce - must still be selectable.
+func TestAdDecisionHandler_FallsBackToStoreScanWhenIndexIsNil(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, "http://localhost:8000", nil, nil)
+	ts := httptest.NewServer(http.HandlerFunc(s.adDecisionHandler))
+	defer ts.Close()
+
+	c := campaign.Campaign{
+		StartTimestamp: time.Now().Add(-time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	}
+	assert.NoError(t, store.Create(context.Background(), &c))
+
+	jsonData, err := json.Marshal(AdRequest{Keywords: []string{"shampoo"}})
+	assert.NoError(t, err)
+
+	resp, err := http.Post(fmt.Sprintf("%s/addecision", ts.URL), "application/json", bytes.NewReader(jsonData))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var responseBody struct {
+		CampaignID int `json:"campaign_id"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&responseBody))
+	assert.Equal(t, c.ID, responseBody.CampaignID)
+}
+
+func TestHandler_UnauthenticatedCampaignCreateIsRejected(t *testing.T) {
+	s := New(storage.NewMemory(), "http://localhost:8000", httpmw.TokenStore{"good-token": "adv-1"}, index.New())
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	c := campaign.Campaign{
+		StartTimestamp: time.Now().Add(time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(2 * time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	}
+	jsonData, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	resp, err := http.Post(fmt.Sprintf("%s/campaign", ts.URL), "application/json", bytes.NewReader(jsonData))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandler_AuthenticatedCampaignCreateStampsAdvertiserIDAndRunsThroughMiddleware(t *testing.T) {
+	s := New(storage.NewMemory(), "http://localhost:8000", httpmw.TokenStore{"good-token": "adv-1"}, index.New())
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	c := campaign.Campaign{
+		StartTimestamp: time.Now().Add(time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(2 * time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	}
+	jsonData, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/campaign", ts.URL), bytes.NewReader(jsonData))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer good-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(httpmw.RequestIDHeader))
+}
+
+func TestHandler_DeliveriesRequiresTheOwningAdvertiser(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, "http://localhost:8000", httpmw.TokenStore{
+		"owner-token": "adv-1",
+		"other-token": "adv-2",
+	}, index.New())
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Add(-time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		AdvertiserID:   "adv-1",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	url := fmt.Sprintf("%s/campaign/%d/deliveries", ts.URL, c.ID)
+
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer other-token")
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	req.Header.Set("Authorization", "Bearer owner-token")
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCampaignHandler(t *testing.T) {
+	s := New(storage.NewMemory(), "http://localhost:8000", nil, index.New())
+	ts := httptest.NewServer(http.HandlerFunc(s.campaignHandler))
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		campaign       campaign.Campaign
+		expectedStatus int
+	}{
+		{
+			name: "Valid Campaign",
+			campaign: campaign.Campaign{
+				StartTimestamp: time.Now().Add(time.Hour).Unix(),
+				EndTimestamp:   time.Now().Add(2 * time.Hour).Unix(),
+				TargetKeywords: []string{"shampoo"},
+				MaxImpression:  100,
+				CPM:            10,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid Campaign - Missing StartTimestamp",
+			campaign: campaign.Campaign{
+				EndTimestamp:   time.Now().Add(2 * time.Hour).Unix(),
+				TargetKeywords: []string{"shampoo"},
+				MaxImpression:  100,
+				CPM:            10,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid Campaign - Missing EndTimestamp",
+			campaign: campaign.Campaign{
+				StartTimestamp: time.Now().Add(time.Hour).Unix(),
+				TargetKeywords: []string{"shampoo"},
+				MaxImpression:  100,
+				CPM:            10,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(tc.campaign)
+			assert.NoError(t, err)
+
+			resp, err := http.Post(fmt.Sprintf("%s/campaign", ts.URL), "application/json", bytes.NewReader(jsonData))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedStatus == http.StatusOK {
+				var responseBody struct {
+					CampaignID int `json:"campaign_id"`
+				}
+				err = json.NewDecoder(resp.Body).Decode(&responseBody)
+				assert.NoError(t, err)
+				assert.NotEqual(t, 0, responseBody.CampaignID)
+			}
+		})
+	}
+}
+
+func TestAdDecisionHandler(t *testing.T) {
+	store := storage.NewMemory()
+	idx := index.New()
+	s := New(store, "http://localhost:8000", nil, idx)
+	ts := httptest.NewServer(http.HandlerFunc(s.adDecisionHandler))
+	defer ts.Close()
+
+	// Add a sample campaign
+	c := campaign.Campaign{
+		StartTimestamp: time.Now().Add(-time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	}
+	assert.NoError(t, store.Create(context.Background(), &c))
+	idx.Add(&c)
+
+	tests := []struct {
+		name           string
+		adRequest      AdRequest
+		expectedStatus int
+	}{
+		{
+			name:           "Valid Ad Request",
+			adRequest:      AdRequest{Keywords: []string{"shampoo"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Ad Request - Missing Keywords",
+			adRequest:      AdRequest{Keywords: []string{}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "No Matching Campaign",
+			adRequest:      AdRequest{Keywords: []string{"nonexistent"}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(tc.adRequest)
+			assert.NoError(t, err)
+
+			resp, err := http.Post(fmt.Sprintf("%s/addecision", ts.URL), "application/json", bytes.NewReader(jsonData))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedStatus == http.StatusOK && strings.Contains(tc.name, "Valid Ad Request") {
+				var responseBody struct {
+					CampaignID    int    `json:"campaign_id"`
+					ImpressionURL string `json:"impression_url"`
+				}
+				err = json.NewDecoder(resp.Body).Decode(&responseBody)
+				assert.NoError(t, err)
+				assert.NotEqual(t, 0, responseBody.CampaignID)
+				assert.NotEmpty(t, responseBody.ImpressionURL)
+			}
+		})
+	}
+}
+
+func TestImpressionHandler(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, "http://localhost:8000", nil, index.New())
+	ts := httptest.NewServer(http.HandlerFunc(s.impressionHandler))
+	defer ts.Close()
+
+	impressionID := generateUUID()
+	c := campaign.Campaign{
+		StartTimestamp:  time.Now().Add(-time.Hour).Unix(),
+		EndTimestamp:    time.Now().Add(time.Hour).Unix(),
+		TargetKeywords:  []string{"shoes"},
+		MaxImpression:   100,
+		CPM:             10,
+		ImpressionCount: 0,
+		ImpressionIds:   []string{impressionID},
+	}
+	assert.NoError(t, store.Create(context.Background(), &c))
+
+	tests := []struct {
+		name           string
+		impressionID   string
+		expectedStatus int
+	}{
+		{
+			name:           "Valid Impression",
+			impressionID:   impressionID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Impression - Nonexistent ID",
+			impressionID:   "nonexistent",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Impression - Empty ID",
+			impressionID:   "",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Method - POST",
+			impressionID:   impressionID,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Invalid Method - PUT",
+			impressionID:   impressionID,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		// More test cases ...
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var req *http.Request
+			var err error
+
+			if tc.name == "Invalid Method - POST" {
+				req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s", ts.URL, tc.impressionID), nil)
+			} else if tc.name == "Invalid Method - PUT" {
+				req, err = http.NewRequest(http.MethodPut, fmt.Sprintf("%s/%s", ts.URL, tc.impressionID), nil)
+			} else {
+				req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s", ts.URL, tc.impressionID), nil)
+			}
+			assert.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
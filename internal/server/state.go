@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/stevenmays/adserver/internal/httpmw"
+	"github.com/stevenmays/adserver/internal/index"
+	"github.com/stevenmays/adserver/internal/storage"
+)
+
+// shutdownTimeout bounds how long State.Run waits for in-flight
+// requests to finish once asked to stop.
+const shutdownTimeout = 5 * time.Second
+
+// State runs the adserver's HTTP server as a process.State, so it can
+// be started alongside future subsystems (a metrics server, a webhook
+// dispatcher) under the same signal handling and shutdown sequencing.
+// Store, BaseURL, and Tokens must be set before Run is called, and
+// Index may be left nil (see New); Flags is registered before they're
+// known, since storage backend
+// selection happens in the same CLI action that calls Run.
+type State struct {
+	Addr    string
+	Store   storage.Interface
+	BaseURL string
+	Tokens  httpmw.TokenStore
+	Index   *index.Index
+}
+
+// NewState returns a State listening on the default address (":8000"),
+// overridable via the -http-addr flag registered in Flags.
+func NewState() *State {
+	return &State{Addr: ":8000"}
+}
+
+func (s *State) Name() string { return "http" }
+
+func (s *State) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "http-addr",
+			Value:       s.Addr,
+			Usage:       "address for the HTTP server to listen on",
+			Destination: &s.Addr,
+		},
+	}
+}
+
+// Run starts the HTTP server and blocks until ctx is cancelled, at
+// which point it drains in-flight requests via http.Server.Shutdown.
+func (s *State) Run(ctx context.Context) error {
+	httpSrv := &http.Server{
+		Addr:    s.Addr,
+		Handler: New(s.Store, s.BaseURL, s.Tokens, s.Index).Handler(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpSrv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return httpSrv.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Backend names the storage implementation New should construct.
+type Backend string
+
+const (
+	// BackendMemory keeps campaigns in a process-local map. Fine for
+	// local development, but two instances won't see each other's
+	// writes.
+	BackendMemory Backend = "memory"
+
+	// BackendEtcd persists campaigns in etcd so multiple adserver
+	// instances can share state safely.
+	BackendEtcd Backend = "etcd"
+)
+
+// Config selects and configures a storage backend.
+type Config struct {
+	Backend Backend
+
+	// EtcdEndpoints and EtcdDialTimeout are only used when Backend is
+	// BackendEtcd.
+	EtcdEndpoints   []string
+	EtcdDialTimeout time.Duration
+}
+
+// New constructs the Interface described by cfg.
+func New(cfg Config) (Interface, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemory(), nil
+	case BackendEtcd:
+		dialTimeout := cfg.EtcdDialTimeout
+		if dialTimeout == 0 {
+			dialTimeout = 5 * time.Second
+		}
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   cfg.EtcdEndpoints,
+			DialTimeout: dialTimeout,
+			Context:     context.Background(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storage: connecting to etcd: %w", err)
+		}
+		return NewEtcd(client), nil
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
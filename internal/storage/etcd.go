@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+const (
+	etcdKeyPrefix  = "/adserver/campaigns/"
+	etcdCounterKey = "/adserver/campaigns/_counter"
+
+	// etcdUpdateRetries bounds how many commit attempts, the first
+	// included, GuaranteedUpdate and nextID make before giving up with
+	// ErrConflict. Each attempt after the first only happens when another
+	// instance wins the race on the same key.
+	etcdUpdateRetries = 5
+)
+
+// etcdStore is a storage.Interface backed by etcd v3, suitable for
+// running more than one adserver instance against the same campaign
+// state. Reads and writes use ModRevision-based optimistic concurrency:
+// GuaranteedUpdate never blindly overwrites a key, it transacts on the
+// ModRevision it last observed and retries against the loser's fresh
+// value on conflict. RPCs are bound by whatever deadline the caller's
+// ctx already carries (see internal/server's requestContext), so an
+// HTTP handler's deadline aborts in-flight etcd calls without this
+// package needing its own deadline bookkeeping.
+type etcdStore struct {
+	client *clientv3.Client
+}
+
+// NewEtcd returns a storage.Interface backed by the given etcd client.
+// The caller owns the client's lifecycle (including Close).
+func NewEtcd(client *clientv3.Client) Interface {
+	return &etcdStore{client: client}
+}
+
+func (s *etcdStore) Create(ctx context.Context, c *campaign.Campaign) error {
+	id, err := s.nextID(ctx)
+	if err != nil {
+		return err
+	}
+	c.ID = id
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Put(ctx, etcdKey(id), string(data))
+	return err
+}
+
+func (s *etcdStore) Get(ctx context.Context, id int) (*campaign.Campaign, error) {
+	resp, err := s.client.Get(ctx, etcdKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, ErrNotFound
+	}
+	return decodeCampaign(resp.Kvs[0].Value)
+}
+
+func (s *etcdStore) List(ctx context.Context) ([]*campaign.Campaign, error) {
+	resp, err := s.client.Get(ctx, etcdKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*campaign.Campaign, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if string(kv.Key) == etcdCounterKey {
+			continue
+		}
+		c, err := decodeCampaign(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// GuaranteedUpdate implements the read-modify-write-on-match loop: read
+// the key with its ModRevision, run tryUpdate, then commit with
+// `If(ModRevision == observed).Then(Put).Else(Get)`. A failed compare
+// means someone else wrote in between, so we loop with the value the
+// Else branch just handed back.
+func (s *etcdStore) GuaranteedUpdate(ctx context.Context, id int, tryUpdate UpdateFunc) (*campaign.Campaign, error) {
+	key := etcdKey(id)
+
+	resp, err := s.client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, ErrNotFound
+	}
+	kv := resp.Kvs[0]
+
+	for attempt := 0; attempt < etcdUpdateRetries; attempt++ {
+		current, err := decodeCampaign(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := tryUpdate(current)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return nil, err
+		}
+
+		txnResp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", kv.ModRevision)).
+			Then(clientv3.OpPut(key, string(data))).
+			Else(clientv3.OpGet(key)).
+			Commit()
+		if err != nil {
+			return nil, err
+		}
+		if txnResp.Succeeded {
+			return updated, nil
+		}
+
+		getResp := txnResp.Responses[0].GetResponseRange()
+		if len(getResp.Kvs) == 0 {
+			return nil, ErrNotFound
+		}
+		kv = getResp.Kvs[0]
+	}
+
+	return nil, ErrConflict
+}
+
+// nextID allocates a campaign ID by transacting on a dedicated counter
+// key, so IDs stay unique across every instance sharing this etcd
+// cluster (len(campaigns)+1001 only worked with a single in-process
+// slice). The first campaign gets ID 1001, matching memoryStore.
+func (s *etcdStore) nextID(ctx context.Context) (int, error) {
+	for attempt := 0; attempt < etcdUpdateRetries; attempt++ {
+		resp, err := s.client.Get(ctx, etcdCounterKey)
+		if err != nil {
+			return 0, err
+		}
+
+		next := 1001
+		var modRevision int64
+		if len(resp.Kvs) > 0 {
+			kv := resp.Kvs[0]
+			modRevision = kv.ModRevision
+			if _, err := fmt.Sscanf(string(kv.Value), "%d", &next); err != nil {
+				return 0, err
+			}
+			next++
+		}
+
+		txnResp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(etcdCounterKey), "=", modRevision)).
+			Then(clientv3.OpPut(etcdCounterKey, fmt.Sprintf("%d", next))).
+			Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txnResp.Succeeded {
+			return next, nil
+		}
+	}
+
+	return 0, ErrConflict
+}
+
+func etcdKey(id int) string {
+	return fmt.Sprintf("%s%d", etcdKeyPrefix, id)
+}
+
+func decodeCampaign(data []byte) (*campaign.Campaign, error) {
+	var c campaign.Campaign
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,88 @@
+//go:build integration
+
+package storage
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+// newIntegrationStores returns two independent Interface values backed
+// by the same etcd cluster, modeling two adserver instances sharing
+// state. Set ADSERVER_ETCD_ENDPOINTS (comma separated) to point this at
+// a running etcd; the test is skipped otherwise.
+func newIntegrationStores(t *testing.T) (Interface, Interface) {
+	t.Helper()
+
+	raw := os.Getenv("ADSERVER_ETCD_ENDPOINTS")
+	if raw == "" {
+		t.Skip("ADSERVER_ETCD_ENDPOINTS not set, skipping etcd integration test")
+	}
+	endpoints := strings.Split(raw, ",")
+
+	newStore := func() Interface {
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   endpoints,
+			DialTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("connecting to etcd: %v", err)
+		}
+		t.Cleanup(func() { client.Close() })
+		return NewEtcd(client)
+	}
+
+	return newStore(), newStore()
+}
+
+func TestEtcdGuaranteedUpdate_ConcurrentInstances(t *testing.T) {
+	instanceA, instanceB := newIntegrationStores(t)
+	ctx := context.Background()
+
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Add(-time.Hour).Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  1000,
+		CPM:            10,
+	}
+	if err := instanceA.Create(ctx, c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const incrementsPerInstance = 25
+	increment := func(store Interface) {
+		for i := 0; i < incrementsPerInstance; i++ {
+			_, err := store.GuaranteedUpdate(ctx, c.ID, func(current *campaign.Campaign) (*campaign.Campaign, error) {
+				current.ImpressionCount++
+				return current, nil
+			})
+			if err != nil {
+				t.Errorf("GuaranteedUpdate: %v", err)
+				return
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); increment(instanceA) }()
+	go func() { defer wg.Done(); increment(instanceB) }()
+	wg.Wait()
+
+	final, err := instanceB.Get(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if want := 2 * incrementsPerInstance; final.ImpressionCount != want {
+		t.Errorf("ImpressionCount = %d, want %d (lost update across instances)", final.ImpressionCount, want)
+	}
+}
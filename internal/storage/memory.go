@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+// memoryStore is an in-memory Interface implementation. It's the default
+// backend for local development and single-instance deployments; it has
+// no way to coordinate with another process, so GuaranteedUpdate never
+// actually conflicts with anything outside this one struct's mutex.
+type memoryStore struct {
+	mu        sync.Mutex
+	campaigns map[int]*campaign.Campaign
+	nextID    int
+}
+
+// NewMemory returns a storage.Interface backed by a process-local map.
+// The first campaign created gets ID 1001, matching etcdStore.
+func NewMemory() Interface {
+	return &memoryStore{
+		campaigns: make(map[int]*campaign.Campaign),
+		nextID:    1000,
+	}
+}
+
+func (s *memoryStore) Create(ctx context.Context, c *campaign.Campaign) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	c.ID = s.nextID
+	s.campaigns[c.ID] = cloneCampaign(c)
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, id int) (*campaign.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.campaigns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneCampaign(c), nil
+}
+
+func (s *memoryStore) List(ctx context.Context) ([]*campaign.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*campaign.Campaign, 0, len(s.campaigns))
+	for _, c := range s.campaigns {
+		out = append(out, cloneCampaign(c))
+	}
+	return out, nil
+}
+
+func (s *memoryStore) GuaranteedUpdate(ctx context.Context, id int, tryUpdate UpdateFunc) (*campaign.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.campaigns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	updated, err := tryUpdate(cloneCampaign(current))
+	if err != nil {
+		return nil, err
+	}
+
+	s.campaigns[id] = cloneCampaign(updated)
+	return cloneCampaign(updated), nil
+}
+
+func cloneCampaign(c *campaign.Campaign) *campaign.Campaign {
+	clone := *c
+	clone.TargetKeywords = append([]string(nil), c.TargetKeywords...)
+	clone.ImpressionIds = append([]string(nil), c.ImpressionIds...)
+	return &clone
+}
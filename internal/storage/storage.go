@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+)
+
+// ErrNotFound is returned by Get and GuaranteedUpdate when no campaign
+// exists under the requested ID.
+var ErrNotFound = errors.New("storage: campaign not found")
+
+// ErrConflict is returned by backends (via GuaranteedUpdate) when a write
+// conflict could not be resolved after the backend's internal retry
+// budget was exhausted.
+var ErrConflict = errors.New("storage: too many conflicting writes")
+
+// Interface is implemented by each storage backend the adserver can run
+// against. Campaign state is modeled so that backends with optimistic
+// concurrency control (etcd) can detect and retry on conflicting writes,
+// which is required once more than one adserver instance is running
+// against the same backing store.
+type Interface interface {
+	// Create allocates an ID for c and persists it.
+	Create(ctx context.Context, c *campaign.Campaign) error
+
+	// Get returns the campaign stored under id, or ErrNotFound.
+	Get(ctx context.Context, id int) (*campaign.Campaign, error)
+
+	// List returns every campaign currently in storage. Order is not
+	// guaranteed.
+	List(ctx context.Context) ([]*campaign.Campaign, error)
+
+	// GuaranteedUpdate reads the current value for id, applies tryUpdate,
+	// and writes the result back only if nothing else modified the key
+	// in between. Implementations retry tryUpdate against the latest
+	// value on conflict, so tryUpdate must be side-effect free and safe
+	// to call more than once.
+	GuaranteedUpdate(ctx context.Context, id int, tryUpdate UpdateFunc) (*campaign.Campaign, error)
+}
+
+// UpdateFunc computes the next value for a campaign given its current
+// value. Returning an error aborts the update without retrying.
+type UpdateFunc func(current *campaign.Campaign) (*campaign.Campaign, error)
@@ -0,0 +1,24 @@
+package webhook
+
+import "time"
+
+// Status is the lifecycle state of a single delivery attempt sequence.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusFailed    Status = "failed"
+)
+
+// Delivery tracks one impression callback's progress through the
+// dispatcher, for the /campaign/{id}/deliveries endpoint.
+type Delivery struct {
+	ImpressionID string    `json:"impression_id"`
+	CampaignID   int       `json:"campaign_id"`
+	URL          string    `json:"url"`
+	Status       Status    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"last_error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,272 @@
+// Package webhook delivers signed impression callbacks to a campaign's
+// advertiser-supplied NotifyURL, with retries, backoff, and a
+// dead-letter record for callbacks that never get through.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/storage"
+)
+
+const (
+	// DefaultWorkers is how many deliveries Dispatcher runs concurrently
+	// when NewDispatcher is given a non-positive worker count.
+	DefaultWorkers = 4
+
+	// DefaultMaxAttempts is how many attempts, the first included,
+	// Dispatcher makes at a delivery before giving up and
+	// dead-lettering it.
+	DefaultMaxAttempts = 6
+
+	// baseBackoff and maxBackoff bound the exponential backoff used
+	// between retries when the advertiser doesn't send Retry-After.
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+
+	deliveryTimeout = 10 * time.Second
+)
+
+// payload is the body POSTed to NotifyURL.
+type payload struct {
+	CampaignID   int    `json:"campaign_id"`
+	ImpressionID string `json:"impression_id"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+type job struct {
+	campaignID   int
+	notifyURL    string
+	notifySecret string
+	impressionID string
+	delivery     *Delivery
+}
+
+// Dispatcher delivers impression callbacks via a fixed pool of
+// background workers.
+type Dispatcher struct {
+	store       storage.Interface
+	client      *http.Client
+	maxAttempts int
+	jobs        chan job
+
+	mu         sync.Mutex
+	deliveries map[int][]*Delivery // campaign ID -> its deliveries
+}
+
+// NewDispatcher starts a Dispatcher with the given number of worker
+// goroutines and retry budget; non-positive values fall back to
+// DefaultWorkers / DefaultMaxAttempts. Failed deliveries are recorded
+// as campaign.DeadLetter entries via store.
+func NewDispatcher(store storage.Interface, workers, maxAttempts int) *Dispatcher {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	d := &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		maxAttempts: maxAttempts,
+		jobs:        make(chan job, 256),
+		deliveries:  make(map[int][]*Delivery),
+	}
+
+	for i := 0; i < workers; i++ {
+		go d.worker()
+	}
+
+	return d
+}
+
+// Enqueue schedules an impression callback for c if it has a NotifyURL.
+// It's a no-op otherwise. Queueing the job is bounded by ctx: if every
+// worker is busy (a slow or down advertiser endpoint can hold one for
+// the full retry/backoff sequence) and the queue is full, Enqueue waits
+// only until ctx is done rather than blocking the caller indefinitely,
+// dead-lettering the callback instead so it doesn't silently vanish.
+func (d *Dispatcher) Enqueue(ctx context.Context, c *campaign.Campaign, impressionID string) {
+	if c.NotifyURL == "" {
+		return
+	}
+
+	delivery := &Delivery{
+		ImpressionID: impressionID,
+		CampaignID:   c.ID,
+		URL:          c.NotifyURL,
+		Status:       StatusPending,
+		UpdatedAt:    time.Now(),
+	}
+
+	d.mu.Lock()
+	d.deliveries[c.ID] = append(d.deliveries[c.ID], delivery)
+	d.mu.Unlock()
+
+	j := job{
+		campaignID:   c.ID,
+		notifyURL:    c.NotifyURL,
+		notifySecret: c.NotifySecret,
+		impressionID: impressionID,
+		delivery:     delivery,
+	}
+
+	select {
+	case d.jobs <- j:
+	case <-ctx.Done():
+		log.Printf("webhook: dropping delivery for campaign %d impression %s: %v", c.ID, impressionID, ctx.Err())
+		d.fail(j, 0, fmt.Sprintf("enqueue: %v", ctx.Err()))
+	}
+}
+
+// Deliveries returns a snapshot of every delivery tracked for
+// campaignID, in the order they were enqueued. The returned Deliveries
+// are copies, safe to read without racing the worker goroutines that
+// keep mutating the originals.
+func (d *Dispatcher) Deliveries(campaignID int) []*Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tracked := d.deliveries[campaignID]
+	out := make([]*Delivery, len(tracked))
+	for i, delivery := range tracked {
+		clone := *delivery
+		out[i] = &clone
+	}
+	return out
+}
+
+func (d *Dispatcher) worker() {
+	for j := range d.jobs {
+		d.deliver(j)
+	}
+}
+
+func (d *Dispatcher) deliver(j job) {
+	body, err := json.Marshal(payload{
+		CampaignID:   j.campaignID,
+		ImpressionID: j.impressionID,
+		Timestamp:    time.Now().Unix(),
+	})
+	if err != nil {
+		d.fail(j, 0, err.Error())
+		return
+	}
+
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivered, retryAfter, retryable, attemptErr := d.attempt(j, body, attempt)
+
+		d.mu.Lock()
+		j.delivery.Attempts = attempt
+		j.delivery.UpdatedAt = time.Now()
+		if attemptErr != "" {
+			j.delivery.LastError = attemptErr
+		}
+		d.mu.Unlock()
+
+		if delivered {
+			d.mu.Lock()
+			j.delivery.Status = StatusDelivered
+			d.mu.Unlock()
+			return
+		}
+
+		if !retryable || attempt == d.maxAttempts {
+			d.fail(j, attempt, attemptErr)
+			return
+		}
+
+		if retryAfter < 0 {
+			retryAfter = backoffWithJitter(attempt)
+		}
+		time.Sleep(retryAfter)
+	}
+}
+
+// attempt makes one delivery attempt. An advertiser responding 409
+// (already_seen) counts as delivered, since it means a previous retry
+// got through and the advertiser is telling us not to reprocess it.
+func (d *Dispatcher) attempt(j job, body []byte, attemptNum int) (delivered bool, retryAfter time.Duration, retryable bool, errMsg string) {
+	ts := time.Now().Unix()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, j.notifyURL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, false, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Adserver-Signature", Sign(j.notifySecret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return false, -1, true, err.Error()
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, -1, false, ""
+	case resp.StatusCode == http.StatusConflict:
+		return true, -1, false, ""
+	case resp.StatusCode >= 500:
+		return false, parseRetryAfter(resp.Header.Get("Retry-After")), true, fmt.Sprintf("advertiser returned %d", resp.StatusCode)
+	default:
+		return false, -1, false, fmt.Sprintf("advertiser returned %d", resp.StatusCode)
+	}
+}
+
+func (d *Dispatcher) fail(j job, attempts int, lastError string) {
+	d.mu.Lock()
+	j.delivery.Status = StatusFailed
+	j.delivery.Attempts = attempts
+	j.delivery.LastError = lastError
+	j.delivery.UpdatedAt = time.Now()
+	d.mu.Unlock()
+
+	_, _ = d.store.GuaranteedUpdate(context.Background(), j.campaignID, func(current *campaign.Campaign) (*campaign.Campaign, error) {
+		current.DeadLetters = append(current.DeadLetters, campaign.DeadLetter{
+			ImpressionID: j.impressionID,
+			URL:          j.notifyURL,
+			Attempts:     attempts,
+			LastError:    lastError,
+			FailedAt:     time.Now().Unix(),
+		})
+		return current, nil
+	})
+}
+
+// parseRetryAfter interprets a Retry-After header given in seconds; it
+// returns -1 (meaning "absent, use our own backoff") if the header is
+// missing or isn't a non-negative integer, so a genuine "Retry-After:
+// 0" (retry immediately) isn't confused with no header at all.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return -1
+	}
+	secs, err := strconv.Atoi(header)
+	if err != nil || secs < 0 {
+		return -1
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// backoffWithJitter returns an exponentially increasing delay, capped
+// at maxBackoff, with up to 50% jitter so retrying workers don't all
+// hammer the advertiser in lockstep.
+func backoffWithJitter(attempt int) time.Duration {
+	backoff := baseBackoff << (attempt - 1)
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+	return backoff/2 + jitter
+}
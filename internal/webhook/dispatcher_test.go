@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stevenmays/adserver/internal/campaign"
+	"github.com/stevenmays/adserver/internal/storage"
+)
+
+func waitForStatus(t *testing.T, d *Dispatcher, campaignID int, want Status, timeout time.Duration) *Delivery {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		deliveries := d.Deliveries(campaignID)
+		if len(deliveries) == 1 && deliveries[0].Status == want {
+			return deliveries[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery for campaign %d did not reach status %q in time", campaignID, want)
+	return nil
+}
+
+func TestDispatcher_DeliversOnSuccess(t *testing.T) {
+	var gotSignature string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Adserver-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		NotifyURL:      ts.URL,
+		NotifySecret:   "s3cr3t",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	d := NewDispatcher(store, 1, DefaultMaxAttempts)
+	d.Enqueue(context.Background(), c, "imp-1")
+
+	delivery := waitForStatus(t, d, c.ID, StatusDelivered, time.Second)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.NotEmpty(t, gotSignature)
+}
+
+func TestDispatcher_RetriesOn5xxThenSucceeds(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		NotifyURL:      ts.URL,
+		NotifySecret:   "s3cr3t",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	d := NewDispatcher(store, 1, DefaultMaxAttempts)
+	d.Enqueue(context.Background(), c, "imp-1")
+
+	delivery := waitForStatus(t, d, c.ID, StatusDelivered, time.Second)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	assert.Equal(t, 3, delivery.Attempts)
+}
+
+func TestDispatcher_ConflictCountsAsDelivered(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(struct {
+			Error string `json:"error"`
+		}{Error: "already_seen"})
+	}))
+	defer ts.Close()
+
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		NotifyURL:      ts.URL,
+		NotifySecret:   "s3cr3t",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	d := NewDispatcher(store, 1, DefaultMaxAttempts)
+	d.Enqueue(context.Background(), c, "imp-1")
+
+	waitForStatus(t, d, c.ID, StatusDelivered, time.Second)
+}
+
+func TestDispatcher_DeadLettersAfterMaxAttempts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		NotifyURL:      ts.URL,
+		NotifySecret:   "s3cr3t",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	d := NewDispatcher(store, 1, 2)
+	d.Enqueue(context.Background(), c, "imp-1")
+
+	waitForStatus(t, d, c.ID, StatusFailed, 2*time.Second)
+
+	updated, err := store.Get(context.Background(), c.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, updated.DeadLetters, 1) {
+		assert.Equal(t, "imp-1", updated.DeadLetters[0].ImpressionID)
+		assert.Equal(t, 2, updated.DeadLetters[0].Attempts)
+	}
+}
+
+func TestDispatcher_EnqueueDeadLettersWhenContextDoneBeforeQueued(t *testing.T) {
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+		NotifyURL:      "http://example.invalid",
+		NotifySecret:   "s3cr3t",
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	// An unbuffered jobs channel with no worker draining it means the
+	// send branch of Enqueue's select can never proceed, so an
+	// already-cancelled ctx deterministically takes the drop path
+	// instead of blocking forever.
+	d := &Dispatcher{
+		store:       store,
+		client:      &http.Client{},
+		maxAttempts: DefaultMaxAttempts,
+		jobs:        make(chan job),
+		deliveries:  make(map[int][]*Delivery),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d.Enqueue(ctx, c, "imp-1")
+
+	delivery := waitForStatus(t, d, c.ID, StatusFailed, time.Second)
+	assert.Equal(t, 0, delivery.Attempts)
+	assert.Contains(t, delivery.LastError, "context canceled")
+}
+
+func TestDispatcher_NoNotifyURLIsNoop(t *testing.T) {
+	store := storage.NewMemory()
+	c := &campaign.Campaign{
+		StartTimestamp: time.Now().Unix(),
+		EndTimestamp:   time.Now().Add(time.Hour).Unix(),
+		TargetKeywords: []string{"shampoo"},
+		MaxImpression:  100,
+		CPM:            10,
+	}
+	assert.NoError(t, store.Create(context.Background(), c))
+
+	d := NewDispatcher(store, 1, DefaultMaxAttempts)
+	d.Enqueue(context.Background(), c, "imp-1")
+
+	assert.Empty(t, d.Deliveries(c.ID))
+}
@@ -0,0 +1,26 @@
+// Package process gives every long-running subsystem of the adserver
+// (the HTTP server and the index sweeper today, a metrics server later)
+// a uniform way to register flags and start up under shared signal
+// handling.
+package process
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+)
+
+// State is a subsystem that can be started and stopped by Run.
+type State interface {
+	// Name identifies the state in logs and error messages.
+	Name() string
+
+	// Flags are registered on the CLI app before Run is called, so the
+	// values they bind (via Destination) are in place by the time Run
+	// starts.
+	Flags() []cli.Flag
+
+	// Run starts the state and blocks until ctx is done and the state
+	// has shut itself down, or until it fails outright.
+	Run(ctx context.Context) error
+}
@@ -0,0 +1,47 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Run starts every state concurrently and blocks until all of them have
+// returned. It installs one shared signal handler for SIGINT/SIGTERM so
+// every state observes cancellation through ctx at the same moment;
+// each state is responsible for shutting itself down gracefully (e.g.
+// via http.Server.Shutdown) once ctx is done. The first state to return,
+// for any reason, cancels ctx for every other state too, so one state
+// failing at startup (or simply finishing) doesn't leave its siblings
+// running until an external SIGINT/SIGTERM arrives.
+func Run(parent context.Context, states ...State) error {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(states))
+	for i, s := range states {
+		wg.Add(1)
+		go func(i int, s State) {
+			defer wg.Done()
+			defer cancel()
+			if err := s.Run(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", s.Name(), err)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
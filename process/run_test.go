@@ -0,0 +1,43 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// fakeState is a minimal State for testing Run's shutdown sequencing.
+type fakeState struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func (s *fakeState) Name() string                  { return s.name }
+func (s *fakeState) Flags() []cli.Flag             { return nil }
+func (s *fakeState) Run(ctx context.Context) error { return s.run(ctx) }
+
+func TestRun_OneStateReturningStopsTheOthers(t *testing.T) {
+	wantErr := errors.New("boom")
+
+	failsImmediately := &fakeState{name: "failer", run: func(ctx context.Context) error {
+		return wantErr
+	}}
+	blocksUntilCancelled := &fakeState{name: "blocker", run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}}
+
+	done := make(chan error, 1)
+	go func() { done <- Run(context.Background(), failsImmediately, blocksUntilCancelled) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, wantErr)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after one state failed; it should have cancelled its sibling")
+	}
+}